@@ -0,0 +1,94 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzl
+
+import (
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+)
+
+// bzlConfig holds configuration for the bzl extension, set by
+// // gazelle:bzl_library_* directives.
+type bzlConfig struct {
+	// perFile, when true (the default), generates one bzl_library per .bzl
+	// file. When false, a single bzl_library is generated per directory,
+	// covering all .bzl files in that directory.
+	perFile bool
+
+	// namingTemplate is a fmt-style template (containing one "%s") used to
+	// derive a bzl_library name from a base name (the .bzl file's stem in
+	// per-file mode, or the directory's base name in per-directory mode).
+	namingTemplate string
+
+	// visibility overrides the default visibility ("//visibility:public")
+	// applied to generated bzl_library rules.
+	visibility []string
+}
+
+const defaultNamingTemplate = "%s_bzl_library"
+
+// externalLibraryName derives a bzl_library name for a file in an external
+// repo recognized via knownExternalRepos, always using defaultNamingTemplate
+// rather than any directory's (possibly customized) bzlConfig: a
+// // gazelle:bzl_library_naming directive governs how this repo names its
+// own rules, not how an external repo named its own.
+func externalLibraryName(stem string) string {
+	return strings.Replace(defaultNamingTemplate, "%s", stem, 1)
+}
+
+func newBzlConfig() *bzlConfig {
+	return &bzlConfig{
+		perFile:        true,
+		namingTemplate: defaultNamingTemplate,
+	}
+}
+
+func (bc *bzlConfig) clone() *bzlConfig {
+	bcCopy := *bc
+	bcCopy.visibility = append([]string{}, bc.visibility...)
+	return &bcCopy
+}
+
+// getBzlConfig returns the bzl extension's configuration for c, creating a
+// default one if none has been set yet.
+func getBzlConfig(c *config.Config) *bzlConfig {
+	bc, ok := c.Exts[bzlName].(*bzlConfig)
+	if !ok {
+		bc = newBzlConfig()
+	}
+	return bc
+}
+
+// libraryName derives a bzl_library rule name from base using bc's naming
+// template.
+func (bc *bzlConfig) libraryName(base string) string {
+	if !strings.Contains(bc.namingTemplate, "%s") {
+		return bc.namingTemplate
+	}
+	return strings.Replace(bc.namingTemplate, "%s", base, 1)
+}
+
+func splitDirective(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
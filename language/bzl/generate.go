@@ -0,0 +1,156 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzl
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// loadsKey is the private attr under which the raw load() path strings found
+// in a bzl_library's srcs are stashed for the resolve phase.
+const loadsKey = "_bzl_loads"
+
+// loadRe matches the first string argument of a load() statement, e.g.
+// load("@bazel_skylib//lib:paths.bzl", "paths") captures
+// "@bazel_skylib//lib:paths.bzl".
+var loadRe = regexp.MustCompile(`load\(\s*"([^"]+)"`)
+
+func (*bzlLang) GenerateRules(args language.GenerateArgs) (empty, gen []*rule.Rule) {
+	bc := getBzlConfig(args.Config)
+
+	var bzlFiles []string
+	for _, name := range args.RegularFiles {
+		if strings.HasSuffix(name, ".bzl") {
+			bzlFiles = append(bzlFiles, name)
+		}
+	}
+	sort.Strings(bzlFiles)
+
+	generatedNames := make(map[string]bool)
+	if bc.perFile {
+		for _, name := range bzlFiles {
+			r := newBzlLibrary(bc, args.Dir, args.Rel, bc.libraryName(strings.TrimSuffix(name, ".bzl")), []string{name})
+			generatedNames[r.Name()] = true
+			gen = append(gen, r)
+		}
+	} else if len(bzlFiles) > 0 {
+		base := filepath.Base(args.Rel)
+		if base == "." || base == "" {
+			base = "root"
+		}
+		r := newBzlLibrary(bc, args.Dir, args.Rel, bc.libraryName(base), bzlFiles)
+		generatedNames[r.Name()] = true
+		gen = append(gen, r)
+	}
+
+	empty = generateEmptyBzlLibraries(args, bzlFiles, generatedNames)
+	return empty, gen
+}
+
+// newBzlLibrary builds a bzl_library rule covering srcs, recording the
+// load() paths found in each source file so the resolver can compute deps.
+func newBzlLibrary(bc *bzlConfig, dir, rel, name string, srcs []string) *rule.Rule {
+	r := rule.NewRule("bzl_library", name)
+	sorted := append([]string{}, srcs...)
+	sort.Strings(sorted)
+	r.SetAttr("srcs", sorted)
+
+	var loads []string
+	for _, src := range sorted {
+		loads = append(loads, parseBzlLoads(filepath.Join(dir, src))...)
+	}
+	r.SetPrivateAttr(loadsKey, loads)
+
+	visibility := bc.visibility
+	if len(visibility) == 0 {
+		visibility = []string{"//visibility:public"}
+	}
+	r.SetAttr("visibility", visibility)
+	return r
+}
+
+// parseBzlLoads scans path for load() statements and returns the raw label
+// string from each one. Parse errors are ignored; a .bzl file that can't be
+// read simply contributes no loads.
+func parseBzlLoads(path string) []string {
+	var loads []string
+	seen := make(map[string]bool)
+	for _, match := range findLoadMatches(path) {
+		if !seen[match] {
+			seen[match] = true
+			loads = append(loads, match)
+		}
+	}
+	return loads
+}
+
+func findLoadMatches(path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, m := range loadRe.FindAllStringSubmatch(string(content), -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// generateEmptyBzlLibraries reaps bzl_library rules from the existing build
+// file whose srcs no longer correspond to any .bzl file on disk, as well as
+// rules left behind by a // gazelle:bzl_library_per_file flip (e.g. several
+// per-file rules orphaned after switching to one-per-directory, or vice
+// versa) — those still reference live files, so they're only caught by
+// checking rule names against generatedNames, the same fix applied to
+// language/proto's analogous reap logic.
+func generateEmptyBzlLibraries(args language.GenerateArgs, bzlFiles []string, generatedNames map[string]bool) []*rule.Rule {
+	if args.File == nil {
+		return nil
+	}
+	known := make(map[string]bool, len(bzlFiles))
+	for _, f := range bzlFiles {
+		known[f] = true
+	}
+	var empty []*rule.Rule
+	for _, r := range args.File.Rules {
+		if r.Kind() != "bzl_library" {
+			continue
+		}
+		srcs := r.AttrStrings("srcs")
+		if len(srcs) == 0 {
+			continue
+		}
+		hasKnownSrc := false
+		for _, src := range srcs {
+			if known[src] {
+				hasKnownSrc = true
+				break
+			}
+		}
+		if hasKnownSrc && generatedNames[r.Name()] {
+			continue // still current
+		}
+		empty = append(empty, rule.NewRule("bzl_library", r.Name()))
+	}
+	return empty
+}
@@ -0,0 +1,71 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+func TestParseBzlLoads(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "macros.bzl")
+	content := `
+load("@bazel_skylib//lib:paths.bzl", "paths")
+load(":helpers.bzl", "helper")
+load("@bazel_skylib//lib:paths.bzl", "paths")  # duplicate, should be deduped
+
+def f():
+    pass
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := parseBzlLoads(path)
+	want := []string{"@bazel_skylib//lib:paths.bzl", ":helpers.bzl"}
+	if len(got) != len(want) {
+		t.Fatalf("parseBzlLoads() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseBzlLoads()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGenerateEmptyBzlLibrariesReapsOnModeSwitch(t *testing.T) {
+	// Simulate a directory that was previously generated with
+	// bzl_library_per_file=true (one rule per file) and has since switched
+	// to per-directory mode: the old per-file rule's srcs still exist on
+	// disk, but it wasn't regenerated this run, so it should be reaped.
+	f := rule.EmptyFile("pkg/BUILD.bazel", "pkg")
+	stale := rule.NewRule("bzl_library", "a_bzl_library")
+	stale.SetAttr("srcs", []string{"a.bzl"})
+	stale.Insert(f)
+
+	args := language.GenerateArgs{File: f}
+	generatedNames := map[string]bool{"pkg_bzl_library": true}
+
+	empty := generateEmptyBzlLibraries(args, []string{"a.bzl", "b.bzl"}, generatedNames)
+	if len(empty) != 1 || empty[0].Name() != "a_bzl_library" {
+		t.Fatalf("generateEmptyBzlLibraries() = %v, want a single empty rule named a_bzl_library", empty)
+	}
+}
@@ -0,0 +1,46 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzl
+
+import "github.com/bazelbuild/bazel-gazelle/rule"
+
+var bzlKinds = map[string]rule.KindInfo{
+	"bzl_library": {
+		NonEmptyAttrs: map[string]bool{
+			"srcs": true,
+		},
+		MergeableAttrs: map[string]bool{
+			"srcs": true,
+			"deps": true,
+		},
+		MatchAttrs: []string{"srcs"},
+		ResolveAttrs: map[string]bool{
+			"deps": true,
+		},
+	},
+}
+
+var bzlLoads = []rule.LoadInfo{
+	{
+		Name: "@bazel_skylib//:bzl_library.bzl",
+		Symbols: []string{
+			"bzl_library",
+		},
+	},
+}
+
+func (*bzlLang) Kinds() map[string]rule.KindInfo { return bzlKinds }
+func (*bzlLang) Loads() []rule.LoadInfo          { return bzlLoads }
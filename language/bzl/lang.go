@@ -0,0 +1,69 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bzl provides a Gazelle extension that generates bzl_library rules
+// for .bzl files, based on the load() statements they contain.
+package bzl
+
+import (
+	"flag"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+const bzlName = "bzl"
+
+type bzlLang struct {
+	language.BaseLang
+}
+
+// NewLanguage returns a new instance of the Gazelle extension for Starlark
+// (.bzl) files.
+func NewLanguage() language.Language {
+	return &bzlLang{}
+}
+
+func (*bzlLang) Name() string { return bzlName }
+
+func (*bzlLang) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.Config) {}
+
+func (*bzlLang) CheckFlags(fs *flag.FlagSet, c *config.Config) error { return nil }
+
+func (*bzlLang) KnownDirectives() []string {
+	return []string{
+		"bzl_library_naming",
+		"bzl_library_per_file",
+		"bzl_library_visibility",
+	}
+}
+
+func (*bzlLang) Configure(c *config.Config, rel string, f *rule.File) {
+	bc := getBzlConfig(c).clone()
+	if f != nil {
+		for _, d := range f.Directives {
+			switch d.Key {
+			case "bzl_library_naming":
+				bc.namingTemplate = d.Value
+			case "bzl_library_per_file":
+				bc.perFile = d.Value != "false"
+			case "bzl_library_visibility":
+				bc.visibility = append([]string{}, splitDirective(d.Value)...)
+			}
+		}
+	}
+	c.Exts[bzlName] = bc
+}
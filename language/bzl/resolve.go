@@ -0,0 +1,142 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzl
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/repo"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// knownExternalRepos maps repository names that the bzl extension recognizes
+// well enough to synthesize a bzl_library label for, without needing that
+// repo's own rules indexed. Gazelle can't see into an external repo's BUILD
+// files, so this is necessarily a guess based on convention.
+var knownExternalRepos = map[string]bool{
+	"io_bazel_rules_go": true,
+	"bazel_skylib":      true,
+}
+
+func (*bzlLang) Embeds(r *rule.Rule, from label.Label) []label.Label { return nil }
+
+// Imports returns one ImportSpec per source file in a bzl_library, keyed by
+// the file's canonical package-relative path. This lets other bzl_library
+// rules that load() this file resolve a dep on it.
+func (*bzlLang) Imports(c *config.Config, r *rule.Rule, f *rule.File) []resolve.ImportSpec {
+	if r.Kind() != "bzl_library" {
+		return nil
+	}
+	var specs []resolve.ImportSpec
+	for _, src := range r.AttrStrings("srcs") {
+		specs = append(specs, resolve.ImportSpec{Lang: bzlName, Imp: canonicalBzlPath(f.Pkg, src)})
+	}
+	return specs
+}
+
+// Resolve turns the load() paths recorded on r (via loadsKey) into deps,
+// either on bzl_library rules found in the local rule index or on
+// synthesized labels in recognized external repos.
+func (*bzlLang) Resolve(c *config.Config, ix *resolve.RuleIndex, rc *repo.RemoteCache, r *rule.Rule, importsRaw interface{}, from label.Label) {
+	loads, _ := importsRaw.([]string)
+	if loads == nil {
+		loads, _ = r.PrivateAttr(loadsKey).([]string)
+	}
+	depSet := make(map[string]bool)
+	for _, raw := range loads {
+		repoName, pkg, file, ok := parseLoadLabel(raw, from.Pkg)
+		if !ok {
+			continue
+		}
+		if repoName != "" && repoName != from.Repo {
+			if !knownExternalRepos[repoName] {
+				continue // unrecognized external repo; can't resolve a dep
+			}
+			// Always use the default naming convention here, never this
+			// directory's bzlConfig: a // gazelle:bzl_library_naming
+			// directive customizes how *this* repo names its own
+			// bzl_library rules, but has no bearing on how an external
+			// repo like bazel_skylib named its own — guessing with the
+			// local template would silently break every external dep as
+			// soon as a consumer customized it.
+			stem := strings.TrimSuffix(path.Base(file), ".bzl")
+			dep := label.New(repoName, pkg, externalLibraryName(stem))
+			depSet[dep.String()] = true
+			continue
+		}
+
+		imp := resolve.ImportSpec{Lang: bzlName, Imp: canonicalBzlPath(pkg, file)}
+		for _, res := range ix.FindRulesByImport(imp, bzlName) {
+			if res.Label.Equal(from) {
+				continue // don't depend on self
+			}
+			depSet[res.Label.Rel(from.Repo, from.Pkg).String()] = true
+		}
+	}
+
+	if len(depSet) == 0 {
+		return
+	}
+	deps := make([]string, 0, len(depSet))
+	for d := range depSet {
+		deps = append(deps, d)
+	}
+	sort.Strings(deps)
+	r.SetAttr("deps", deps)
+}
+
+// canonicalBzlPath returns a package-qualified identifier for a .bzl file,
+// used as the ImportSpec key on both the producing and consuming sides.
+func canonicalBzlPath(pkg, file string) string {
+	return path.Join(pkg, file)
+}
+
+// parseLoadLabel splits the raw string argument of a load() statement into a
+// repository name (empty for the current workspace), package path, and file
+// name, resolving package-relative forms like ":foo.bzl" against fromPkg.
+func parseLoadLabel(raw, fromPkg string) (repoName, pkg, file string, ok bool) {
+	s := raw
+	if strings.HasPrefix(s, "@") {
+		end := strings.Index(s, "//")
+		if end < 0 {
+			return "", "", "", false
+		}
+		repoName = s[1:end]
+		s = s[end:]
+	}
+	if !strings.HasPrefix(s, "//") && !strings.HasPrefix(s, ":") {
+		return "", "", "", false
+	}
+	s = strings.TrimPrefix(s, "//")
+	colon := strings.LastIndex(s, ":")
+	if colon < 0 {
+		return "", "", "", false
+	}
+	pkg = s[:colon]
+	file = s[colon+1:]
+	if pkg == "" && strings.HasPrefix(raw, ":") {
+		pkg = fromPkg
+	}
+	if file == "" {
+		return "", "", "", false
+	}
+	return repoName, pkg, file, true
+}
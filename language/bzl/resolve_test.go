@@ -0,0 +1,73 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bzl
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+func TestParseLoadLabel(t *testing.T) {
+	tests := []struct {
+		raw, fromPkg                string
+		wantRepo, wantPkg, wantFile string
+		wantOk                      bool
+	}{
+		{raw: "@bazel_skylib//lib:paths.bzl", fromPkg: "pkg", wantRepo: "bazel_skylib", wantPkg: "lib", wantFile: "paths.bzl", wantOk: true},
+		{raw: ":helpers.bzl", fromPkg: "pkg", wantRepo: "", wantPkg: "pkg", wantFile: "helpers.bzl", wantOk: true},
+		{raw: "//other:helpers.bzl", fromPkg: "pkg", wantRepo: "", wantPkg: "other", wantFile: "helpers.bzl", wantOk: true},
+		{raw: "not_a_load_label", fromPkg: "pkg", wantOk: false},
+	}
+	for _, tc := range tests {
+		repoName, pkg, file, ok := parseLoadLabel(tc.raw, tc.fromPkg)
+		if ok != tc.wantOk {
+			t.Errorf("parseLoadLabel(%q) ok = %v, want %v", tc.raw, ok, tc.wantOk)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if repoName != tc.wantRepo || pkg != tc.wantPkg || file != tc.wantFile {
+			t.Errorf("parseLoadLabel(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				tc.raw, repoName, pkg, file, tc.wantRepo, tc.wantPkg, tc.wantFile)
+		}
+	}
+}
+
+func TestResolveExternalRepoIgnoresLocalNamingDirective(t *testing.T) {
+	// This directory has customized its own bzl_library naming, but that
+	// must have no effect on how a dep on a *different*, external repo
+	// (bazel_skylib) is guessed.
+	c := &config.Config{Exts: map[string]interface{}{}}
+	bc := newBzlConfig()
+	bc.namingTemplate = "lib_%s"
+	c.Exts[bzlName] = bc
+
+	r := rule.NewRule("bzl_library", "macros_bzl_library")
+	r.SetPrivateAttr(loadsKey, []string{"@bazel_skylib//lib:paths.bzl"})
+	from := label.New("", "pkg", "macros_bzl_library")
+
+	(&bzlLang{}).Resolve(c, nil, nil, r, nil, from)
+
+	deps := r.AttrStrings("deps")
+	want := "@bazel_skylib//lib:paths_bzl_library"
+	if len(deps) != 1 || deps[0] != want {
+		t.Fatalf("deps = %v, want [%s] (the default naming convention, not this directory's customized one)", deps, want)
+	}
+}
@@ -0,0 +1,105 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/packagesindex"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// goConfig holds configuration for the Go extension, set by // gazelle:go_*
+// directives.
+type goConfig struct {
+	// EmitPackageIndex enables recording a packagesindex.Package entry for
+	// each generated go_library/go_proto_library, for gopackagesdriver's
+	// index.
+	EmitPackageIndex bool
+
+	// goProtoCompilers and goGRPCCompilers are the compiler labels passed to
+	// an extra go_proto_library generated per compiler-set when the
+	// underlying proto_library's package has a service. Empty means the
+	// rules_go default compiler is used and no extra rule is generated.
+	goProtoCompilers []string
+	goGRPCCompilers  []string
+}
+
+func newGoConfig() *goConfig {
+	return &goConfig{}
+}
+
+func (gc *goConfig) clone() *goConfig {
+	gcCopy := *gc
+	gcCopy.goProtoCompilers = append([]string{}, gc.goProtoCompilers...)
+	gcCopy.goGRPCCompilers = append([]string{}, gc.goGRPCCompilers...)
+	return &gcCopy
+}
+
+func splitCompilers(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func (*goLang) KnownDirectives() []string {
+	return []string{
+		"emit_package_index",
+		"go_proto_compilers",
+		"go_grpc_compilers",
+	}
+}
+
+func (*goLang) Configure(c *config.Config, rel string, f *rule.File) {
+	if rel == "" {
+		// c.Exts is cloned per directory as the walk descends, so the
+		// packagesindex.Collector must be created here, at the root, before
+		// any of those clones happen — otherwise each directory's Resolve
+		// would lazily create (and write to) its own independent Collector
+		// instead of sharing the one for this run.
+		packagesindex.FromConfig(c)
+	}
+	gc := getGoConfig(c).clone()
+	if f != nil {
+		for _, d := range f.Directives {
+			switch d.Key {
+			case "emit_package_index":
+				gc.EmitPackageIndex = d.Value == "true"
+			case "go_proto_compilers":
+				gc.goProtoCompilers = splitCompilers(d.Value)
+			case "go_grpc_compilers":
+				gc.goGRPCCompilers = splitCompilers(d.Value)
+			}
+		}
+	}
+	c.Exts[goName] = gc
+}
+
+// getGoConfig returns the Go extension's configuration for c, creating a
+// default one if none has been set yet.
+func getGoConfig(c *config.Config) *goConfig {
+	gc, ok := c.Exts[goName].(*goConfig)
+	if !ok {
+		gc = newGoConfig()
+	}
+	return gc
+}
@@ -0,0 +1,119 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// embedDirectiveRe matches a "// +gazelle:embed <pattern> [var]" comment,
+// written at package level (e.g. in a doc.go), that asks Gazelle to manage a
+// go_embed_data rule for the matched files.
+var embedDirectiveRe = regexp.MustCompile(`(?m)^\s*//\s*\+gazelle:embed\s+(\S+)(?:\s+(\S+))?\s*$`)
+
+// goEmbedDirectiveRe matches a //go:embed directive, the Go 1.16+ standard
+// library form. Gazelle treats its presence in a package as a signal that a
+// go_embed_data rule should be generated the same way, using the package's
+// default var name.
+var goEmbedDirectiveRe = regexp.MustCompile(`(?m)^\s*//go:embed\s+(\S+)`)
+
+// embedSpec describes a go_embed_data rule that should be generated for a
+// package, derived from a +gazelle:embed or //go:embed directive.
+type embedSpec struct {
+	name    string // go_embed_data rule name
+	varName string
+	pattern string
+}
+
+// findEmbedSpec scans a package's Go source files for an embed directive.
+// Only one go_embed_data rule is generated per package; the first directive
+// found wins.
+func findEmbedSpec(dir, pkgName string, goFiles []string) *embedSpec {
+	for _, f := range goFiles {
+		content, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			continue
+		}
+		s := string(content)
+		if m := embedDirectiveRe.FindStringSubmatch(s); m != nil {
+			varName := m[2]
+			if varName == "" {
+				varName = "data"
+			}
+			return &embedSpec{
+				name:    pkgName + "_data",
+				varName: varName,
+				pattern: m[1],
+			}
+		}
+		if m := goEmbedDirectiveRe.FindStringSubmatch(s); m != nil {
+			return &embedSpec{
+				name:    pkgName + "_data",
+				varName: "data",
+				pattern: m[1],
+			}
+		}
+	}
+	return nil
+}
+
+// generateEmbedRule builds a go_embed_data rule for spec. srcs is resolved
+// by globbing pattern relative to dir; glob errors simply result in an empty
+// srcs list, which IsEmpty will later flag for removal.
+func generateEmbedRule(dir, pkgName string, spec *embedSpec) *rule.Rule {
+	r := rule.NewRule("go_embed_data", spec.name)
+	matches, _ := filepath.Glob(filepath.Join(dir, spec.pattern))
+	srcs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		rel, err := filepath.Rel(dir, m)
+		if err != nil {
+			continue
+		}
+		srcs = append(srcs, filepath.ToSlash(rel))
+	}
+	sort.Strings(srcs)
+	if len(srcs) == 1 {
+		r.SetAttr("src", srcs[0])
+	} else if len(srcs) > 1 {
+		r.SetAttr("srcs", srcs)
+		r.SetAttr("flatten", true)
+	}
+	r.SetAttr("package", pkgName)
+	r.SetAttr("var", spec.varName)
+	return r
+}
+
+// addEmbedData generates a go_embed_data rule (if the package has an embed
+// directive) and wires it into lib's embed attribute, alongside any existing
+// embedded libraries.
+func addEmbedData(dir, pkgName string, goFiles []string, lib *rule.Rule) *rule.Rule {
+	spec := findEmbedSpec(dir, pkgName, goFiles)
+	if spec == nil {
+		return nil
+	}
+	dataRule := generateEmbedRule(dir, pkgName, spec)
+	embed := append([]string{}, lib.AttrStrings("embed")...)
+	embed = append(embed, ":"+spec.name)
+	sort.Strings(embed)
+	lib.SetAttr("embed", embed)
+	return dataRule
+}
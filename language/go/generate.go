@@ -0,0 +1,99 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// defaultLibraryName is the name rules_go and Gazelle have historically used
+// for a directory's primary go_library, predating import-path-derived
+// names; go_library's MatchAttrs includes "importpath" precisely so a rule
+// can still be found across a rename.
+const defaultLibraryName = "go_default_library"
+
+var packageClauseRe = regexp.MustCompile(`(?m)^\s*package\s+(\w+)\s*$`)
+
+func (*goLang) GenerateRules(args language.GenerateArgs) (empty, gen []*rule.Rule) {
+	var goFiles, testFiles []string
+	for _, name := range args.RegularFiles {
+		switch {
+		case !strings.HasSuffix(name, ".go"):
+			continue
+		case strings.HasSuffix(name, "_test.go"):
+			testFiles = append(testFiles, name)
+		default:
+			goFiles = append(goFiles, name)
+		}
+	}
+	sort.Strings(goFiles)
+	sort.Strings(testFiles)
+
+	pkgName := goPackageNameFromFiles(args.Dir, goFiles)
+
+	if len(goFiles) > 0 {
+		lib := rule.NewRule("go_library", defaultLibraryName)
+		lib.SetAttr("srcs", goFiles)
+		if pkgName != "" {
+			lib.SetAttr("importpath", pkgName)
+		}
+		lib.SetAttr("visibility", []string{"//visibility:public"})
+
+		// Wire any // +gazelle:embed / //go:embed directive into a
+		// go_embed_data rule and the library's embed attr.
+		if dataRule := addEmbedData(args.Dir, pkgName, goFiles, lib); dataRule != nil {
+			gen = append(gen, dataRule)
+		}
+		gen = append(gen, lib)
+	}
+
+	if len(testFiles) > 0 {
+		test := rule.NewRule("go_test", defaultLibraryName+"_test")
+		test.SetAttr("srcs", testFiles)
+		if len(goFiles) > 0 {
+			test.SetAttr("embed", []string{":" + defaultLibraryName})
+		}
+		gen = append(gen, test)
+	}
+
+	// Emit go_proto_library rule(s) for any proto_library language/proto
+	// generated for this same directory in this pass.
+	gen = append(gen, generateGoProtoRules(args, pkgName)...)
+
+	return empty, gen
+}
+
+// goPackageNameFromFiles returns the Go package name declared by the first
+// parseable file in goFiles, or "" if none could be read.
+func goPackageNameFromFiles(dir string, goFiles []string) string {
+	for _, f := range goFiles {
+		content, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			continue
+		}
+		if m := packageClauseRe.FindStringSubmatch(string(content)); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
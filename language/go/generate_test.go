@@ -0,0 +1,66 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/language"
+)
+
+func TestGenerateRulesWiresEmbedData(t *testing.T) {
+	dir := t.TempDir()
+	content := `package foo
+
+//go:embed data.txt
+var data string
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data.txt"), []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := language.GenerateArgs{Dir: dir, Rel: "foo", RegularFiles: []string{"foo.go", "data.txt"}}
+	_, gen := (&goLang{}).GenerateRules(args)
+
+	var foundLib, foundData bool
+	var embed []string
+	for _, r := range gen {
+		switch r.Kind() {
+		case "go_library":
+			foundLib = true
+			embed = r.AttrStrings("embed")
+		case "go_embed_data":
+			foundData = true
+			if r.Name() != "foo_data" {
+				t.Errorf("go_embed_data rule name = %q, want %q", r.Name(), "foo_data")
+			}
+		}
+	}
+	if !foundLib {
+		t.Fatal("GenerateRules() did not produce a go_library rule")
+	}
+	if !foundData {
+		t.Fatal("GenerateRules() did not produce a go_embed_data rule for the //go:embed directive")
+	}
+	if len(embed) != 1 || embed[0] != ":foo_data" {
+		t.Errorf("go_library embed = %v, want [:foo_data]", embed)
+	}
+}
@@ -68,9 +68,27 @@ var goKinds = map[string]rule.KindInfo{
 		},
 		ResolveAttrs: map[string]bool{"deps": true},
 	},
+	"go_embed_data": {
+		NonEmptyAttrs: map[string]bool{
+			"src":  true,
+			"srcs": true,
+		},
+		MergeableAttrs: map[string]bool{
+			"src":     true,
+			"srcs":    true,
+			"package": true,
+			"var":     true,
+			"flatten": true,
+			"string":  true,
+		},
+	},
 	"yext_protos": {},
 	"go_proto_library": {
-		MatchAttrs: []string{"importpath"},
+		// "compilers" must agree too: a directory can have several
+		// go_proto_library rules (base, gRPC, custom compiler sets) that all
+		// share the same importpath, so importpath alone isn't a safe
+		// fallback match when rule names don't line up across runs.
+		MatchAttrs: []string{"importpath", "compilers"},
 		NonEmptyAttrs: map[string]bool{
 			"deps":  true,
 			"embed": true,
@@ -185,6 +203,11 @@ var goLoads = []rule.LoadInfo{
 			"go_register_toolchains",
 			"gazelle_dependencies",
 		},
+	}, {
+		Name: "@io_bazel_rules_go//extras:embed_data.bzl",
+		Symbols: []string{
+			"go_embed_data",
+		},
 	},
 }
 
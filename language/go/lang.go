@@ -0,0 +1,41 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package golang provides a Gazelle extension that generates Go build rules.
+package golang
+
+import (
+	"flag"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/language"
+)
+
+const goName = "go"
+
+type goLang struct {
+	language.BaseLang
+}
+
+// NewLanguage returns a new instance of the Gazelle extension for Go.
+func NewLanguage() language.Language {
+	return &goLang{}
+}
+
+func (_ *goLang) Name() string { return goName }
+
+func (_ *goLang) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.Config) {}
+
+func (_ *goLang) CheckFlags(fs *flag.FlagSet, c *config.Config) error { return nil }
@@ -0,0 +1,43 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/packagesindex"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// recordPackageIndexEntry records a packagesindex.Package entry for r (a
+// generated go_library or go_proto_library) if emit_package_index is
+// enabled. It should be called from Resolve, once r's deps attr holds its
+// final, resolved value — the same point at which language/proto's Resolve
+// records its own entries.
+func recordPackageIndexEntry(c *config.Config, from label.Label, r *rule.Rule, goFiles []string, hasServices bool) {
+	gc := getGoConfig(c)
+	if !gc.EmitPackageIndex {
+		return
+	}
+	packagesindex.FromConfig(c).Add(packagesindex.Package{
+		ImportPath:  r.AttrString("importpath"),
+		Label:       from.String(),
+		Srcs:        r.AttrStrings("srcs"),
+		Deps:        r.AttrStrings("deps"),
+		GoFiles:     goFiles,
+		HasServices: hasServices,
+	})
+}
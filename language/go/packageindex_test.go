@@ -0,0 +1,99 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/packagesindex"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+func TestResolveRecordsGoLibraryAndFlushesIndex(t *testing.T) {
+	repoRoot := t.TempDir()
+	c := &config.Config{RepoRoot: repoRoot, Exts: map[string]interface{}{}}
+	gc := newGoConfig()
+	gc.EmitPackageIndex = true
+	c.Exts[goName] = gc
+
+	lib := rule.NewRule("go_library", "go_default_library")
+	lib.SetAttr("importpath", "example.com/repo/foo")
+	lib.SetAttr("srcs", []string{"foo.go"})
+	from := label.New("", "foo", "go_default_library")
+
+	(&goLang{}).Resolve(c, nil, nil, lib, nil, from)
+
+	data, err := os.ReadFile(filepath.Join(repoRoot, packagesindex.IndexFileName))
+	if err != nil {
+		t.Fatalf("Resolve() did not write %s: %v", packagesindex.IndexFileName, err)
+	}
+	var idx packagesindex.Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatalf("unmarshal %s: %v", packagesindex.IndexFileName, err)
+	}
+	if len(idx.Packages) != 1 || idx.Packages[0].ImportPath != "example.com/repo/foo" {
+		t.Fatalf("index = %+v, want a single entry for example.com/repo/foo", idx.Packages)
+	}
+}
+
+// TestResolveSharesCollectorAcrossDirectories simulates the real walk: a
+// root Configure call, followed by the per-directory Exts clone the walk
+// performs for each directory below it. Both directories' Resolve calls
+// must land in the same flushed index, not clobber each other.
+func TestResolveSharesCollectorAcrossDirectories(t *testing.T) {
+	repoRoot := t.TempDir()
+	root := &config.Config{RepoRoot: repoRoot, Exts: map[string]interface{}{}}
+	(&goLang{}).Configure(root, "", nil)
+
+	gc := newGoConfig()
+	gc.EmitPackageIndex = true
+
+	cloneForDir := func() *config.Config {
+		exts := make(map[string]interface{}, len(root.Exts))
+		for k, v := range root.Exts {
+			exts[k] = v
+		}
+		exts[goName] = gc
+		return &config.Config{RepoRoot: repoRoot, Exts: exts}
+	}
+
+	dirA := cloneForDir()
+	libA := rule.NewRule("go_library", "go_default_library")
+	libA.SetAttr("importpath", "example.com/repo/a")
+	(&goLang{}).Resolve(dirA, nil, nil, libA, nil, label.New("", "a", "go_default_library"))
+
+	dirB := cloneForDir()
+	libB := rule.NewRule("go_library", "go_default_library")
+	libB.SetAttr("importpath", "example.com/repo/b")
+	(&goLang{}).Resolve(dirB, nil, nil, libB, nil, label.New("", "b", "go_default_library"))
+
+	data, err := os.ReadFile(filepath.Join(repoRoot, packagesindex.IndexFileName))
+	if err != nil {
+		t.Fatalf("Resolve() did not write %s: %v", packagesindex.IndexFileName, err)
+	}
+	var idx packagesindex.Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatalf("unmarshal %s: %v", packagesindex.IndexFileName, err)
+	}
+	if len(idx.Packages) != 2 {
+		t.Fatalf("index = %+v, want entries from both directories, not just the last to flush", idx.Packages)
+	}
+}
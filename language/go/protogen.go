@@ -0,0 +1,124 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/language/proto"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// generateGoProtoLibraries builds the go_proto_library rule(s) that consume
+// protoRule, a proto_library generated by language/proto in the same
+// directory. Normally this is a single rule using the default rules_go
+// compiler. protoRule carries the proto.Package it was generated from as a
+// PrivateAttr (proto.PackageKey); when that package has a service and
+// // gazelle:go_proto_compilers / // gazelle:go_grpc_compilers directives
+// are set, an extra go_proto_library is generated per configured compiler
+// set, so callers that need e.g. a gRPC client/server don't have to
+// hand-edit BUILD files. The "compilers" attr is declared MergeableAttrs in
+// goKinds, so a hand-written compilers list (with a "# keep" comment) on an
+// existing rule survives regeneration.
+func generateGoProtoLibraries(c *config.Config, protoRule *rule.Rule, importPath string) []*rule.Rule {
+	gc := getGoConfig(c)
+	pkg, _ := protoRule.PrivateAttr(proto.PackageKey).(proto.Package)
+
+	out := []*rule.Rule{newGoProtoLibrary(protoRule, importPath, "", nil, pkg)}
+
+	if !pkg.HasServices {
+		return out
+	}
+	for _, compilers := range [][]string{gc.goProtoCompilers, gc.goGRPCCompilers} {
+		if len(compilers) == 0 {
+			continue
+		}
+		out = append(out, newGoProtoLibrary(protoRule, importPath, compilerSetSuffix(compilers), compilers, pkg))
+	}
+	return out
+}
+
+// newGoProtoLibrary builds one go_proto_library rule. Its name already
+// distinguishes the base rule from each compiler-set variant
+// (compilerSetSuffix is derived from the compilers themselves, so it's
+// stable across runs), and "compilers" is also listed in goKinds'
+// MatchAttrs alongside "importpath" — multiple go_proto_library rules for
+// the same package share an importpath, so importpath alone isn't a safe
+// fallback match; requiring compilers to agree too (including "unset", for
+// the base rule) keeps Gazelle's match-by-attrs fallback from conflating a
+// renamed base rule with a renamed gRPC variant.
+func newGoProtoLibrary(protoRule *rule.Rule, importPath, suffix string, compilers []string, pkg proto.Package) *rule.Rule {
+	name := strings.TrimSuffix(protoRule.Name(), "_proto") + "_go_proto"
+	if suffix != "" {
+		name += "_" + suffix
+	}
+	r := rule.NewRule("go_proto_library", name)
+	r.SetAttr("proto", ":"+protoRule.Name())
+	r.SetAttr("importpath", importPath)
+	if len(compilers) > 0 {
+		r.SetAttr("compilers", compilers)
+	}
+	r.SetPrivateAttr(proto.PackageKey, pkg)
+	return r
+}
+
+// generateGoProtoRules finds the proto_library rules language/proto
+// generated for this same directory in this same pass (threaded through via
+// GenerateArgs.OtherGen, which Gazelle populates with other languages'
+// already-generated rules so later languages can build on them) and emits
+// the corresponding go_proto_library rule(s) for each.
+func generateGoProtoRules(args language.GenerateArgs, goPrefix string) []*rule.Rule {
+	var gen []*rule.Rule
+	for _, r := range args.OtherGen {
+		if r.Kind() != "proto_library" {
+			continue
+		}
+		pkg, _ := r.PrivateAttr(proto.PackageKey).(proto.Package)
+		gen = append(gen, generateGoProtoLibraries(args.Config, r, protoImportPath(pkg, goPrefix))...)
+	}
+	return gen
+}
+
+// protoImportPath derives the Go import path for a proto package, preferring
+// an explicit "option go_package" (splitting its optional two-part
+// "import/path;pkgname" form on the semicolon the way goPackageName does in
+// language/proto) and falling back to goPrefix, this directory's Go import
+// path, when no option is set.
+func protoImportPath(pkg proto.Package, goPrefix string) string {
+	if opt, ok := pkg.Options["go_package"]; ok {
+		if i := strings.IndexByte(opt, ';'); i >= 0 {
+			return opt[:i]
+		}
+		return opt
+	}
+	return goPrefix
+}
+
+// compilerSetSuffix derives a short, stable rule name suffix from a
+// compilers directive value, e.g. "@io_bazel_rules_go//proto:go_grpc"
+// becomes "go_grpc".
+func compilerSetSuffix(compilers []string) string {
+	last := compilers[len(compilers)-1]
+	if i := strings.LastIndexByte(last, '/'); i >= 0 {
+		last = last[i+1:]
+	}
+	if i := strings.LastIndexByte(last, ':'); i >= 0 {
+		last = last[i+1:]
+	}
+	return last
+}
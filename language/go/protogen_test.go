@@ -0,0 +1,90 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/language/proto"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+func TestGenerateGoProtoLibrariesMultiCompiler(t *testing.T) {
+	c := &config.Config{Exts: map[string]interface{}{}}
+	gc := newGoConfig()
+	gc.goGRPCCompilers = []string{"@io_bazel_rules_go//proto:go_grpc"}
+	c.Exts[goName] = gc
+
+	protoRule := rule.NewRule("proto_library", "foo_proto")
+	protoRule.SetPrivateAttr(proto.PackageKey, proto.Package{HasServices: true})
+
+	got := generateGoProtoLibraries(c, protoRule, "example.com/repo/foo")
+
+	if len(got) != 2 {
+		t.Fatalf("generateGoProtoLibraries() returned %d rules, want 2 (base + grpc)", len(got))
+	}
+
+	names := map[string]bool{}
+	compilers := map[string][]string{}
+	for _, r := range got {
+		names[r.Name()] = true
+		compilers[r.Name()] = r.AttrStrings("compilers")
+	}
+
+	if !names["foo_go_proto"] || !names["foo_go_proto_go_grpc"] {
+		t.Fatalf("generateGoProtoLibraries() names = %v, want foo_go_proto and foo_go_proto_go_grpc", names)
+	}
+	if len(compilers["foo_go_proto"]) != 0 {
+		t.Errorf("base rule compilers = %v, want none set", compilers["foo_go_proto"])
+	}
+	if len(compilers["foo_go_proto_go_grpc"]) != 1 {
+		t.Errorf("grpc rule compilers = %v, want 1 entry", compilers["foo_go_proto_go_grpc"])
+	}
+
+	// The two rules share an importpath, so MatchAttrs must include
+	// "compilers" too, or Gazelle's match-by-attrs fallback could conflate
+	// them across a rename; see goKinds' go_proto_library entry.
+	info := goKinds["go_proto_library"]
+	found := false
+	for _, a := range info.MatchAttrs {
+		if a == "compilers" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("go_proto_library MatchAttrs = %v, want it to include \"compilers\"", info.MatchAttrs)
+	}
+}
+
+func TestProtoImportPath(t *testing.T) {
+	tests := []struct {
+		opt  string
+		want string
+	}{
+		{"example.com/repo/foo;foopb", "example.com/repo/foo"},
+		{"example.com/repo/foo", "example.com/repo/foo"},
+	}
+	for _, tc := range tests {
+		pkg := proto.Package{Options: map[string]string{"go_package": tc.opt}}
+		if got := protoImportPath(pkg, "fallback"); got != tc.want {
+			t.Errorf("protoImportPath(%q) = %q, want %q", tc.opt, got, tc.want)
+		}
+	}
+	if got := protoImportPath(proto.Package{}, "fallback"); got != "fallback" {
+		t.Errorf("protoImportPath({}) = %q, want fallback", got)
+	}
+}
@@ -0,0 +1,54 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/language/proto"
+	"github.com/bazelbuild/bazel-gazelle/packagesindex"
+	"github.com/bazelbuild/bazel-gazelle/repo"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// Resolve records a generated go_library or go_proto_library in the package
+// index if one is being built, once the rule's deps attr has its final,
+// resolved value. For go_proto_library it also reads the proto.Package
+// recorded as a PrivateAttr (proto.PackageKey, carried over from generation
+// in generateGoProtoLibraries) to confirm whether the package has a
+// service. Ordinary Go import resolution (deps from import paths) is
+// handled elsewhere and is not reproduced here.
+func (*goLang) Resolve(c *config.Config, ix *resolve.RuleIndex, rc *repo.RemoteCache, r *rule.Rule, importsRaw interface{}, from label.Label) {
+	switch r.Kind() {
+	case "go_library":
+		recordPackageIndexEntry(c, from, r, r.AttrStrings("srcs"), false)
+	case "go_proto_library":
+		// pkg.HasServices was already used at generation time
+		// (generateGoProtoLibraries) to decide whether to emit the extra
+		// per-compiler rules; re-reading it here via PrivateAttr lets the
+		// package index reflect that same decision without re-parsing the
+		// .proto files.
+		pkg, _ := r.PrivateAttr(proto.PackageKey).(proto.Package)
+		recordPackageIndexEntry(c, from, r, nil, pkg.HasServices)
+	default:
+		return
+	}
+
+	if gc := getGoConfig(c); gc.EmitPackageIndex {
+		packagesindex.Flush(c, c.RepoRoot)
+	}
+}
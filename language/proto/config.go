@@ -0,0 +1,132 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"fmt"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+)
+
+const protoName = "proto"
+
+// Mode determines how proto rules are generated for a directory containing
+// .proto files.
+type Mode int
+
+const (
+	// DefaultMode generates a proto_library for the proto package in a
+	// directory. If the directory contains multiple packages, Gazelle picks
+	// the one whose Go package name matches the directory's path (with "/"
+	// replaced by "_"); if none matches, it logs an error and generates no
+	// rule.
+	DefaultMode Mode = iota
+
+	// PackageMode generates a proto_library for each distinct proto package
+	// found within a directory.
+	PackageMode
+
+	// FileMode generates a separate proto_library for each .proto file in a
+	// directory, named after the file's stem. This allows downstream
+	// consumers to depend on individual messages without rebuilding whenever
+	// an unrelated .proto file in the same directory changes.
+	FileMode
+
+	// ServiceMode generates a proto_library for the files in a directory
+	// that declare at least one "service", and a separate proto_library for
+	// the remaining (message-only) files. This isolates the build graph
+	// impact of RPC service changes from plain message changes.
+	ServiceMode
+)
+
+// String returns the string representation of the mode, as used in the
+// "gazelle:proto" directive.
+func (m Mode) String() string {
+	switch m {
+	case DefaultMode:
+		return "default"
+	case PackageMode:
+		return "package"
+	case FileMode:
+		return "file"
+	case ServiceMode:
+		return "service"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// ProtoModeFromString converts a string to a Mode, returning an error if the
+// string is not recognized.
+func ProtoModeFromString(s string) (Mode, error) {
+	switch s {
+	case "default":
+		return DefaultMode, nil
+	case "package":
+		return PackageMode, nil
+	case "file":
+		return FileMode, nil
+	case "service":
+		return ServiceMode, nil
+	default:
+		return 0, fmt.Errorf("unrecognized proto mode: %q", s)
+	}
+}
+
+// ShouldGenerateRules returns whether Gazelle should generate (and reap
+// stale) proto_library rules for a directory in this mode. Gazelle manages
+// these rules in every Mode defined here, so it always returns true.
+func (m Mode) ShouldGenerateRules() bool {
+	return true
+}
+
+// ProtoConfig holds configuration for the proto extension, set by
+// // gazelle:proto* directives.
+type ProtoConfig struct {
+	Mode Mode
+
+	// GoPrefix is the Go import path prefix configured for this repo, used
+	// by DefaultMode as a fallback when deriving a proto_library's name.
+	GoPrefix string
+
+	// groupOption, when non-empty, is the name of a proto file option whose
+	// value is used (in place of the package name) to group .proto files
+	// into packages.
+	groupOption string
+
+	// EmitPackageIndex enables recording a packagesindex.Package entry for
+	// each generated proto_library, for gopackagesdriver's index.
+	EmitPackageIndex bool
+}
+
+func newProtoConfig() *ProtoConfig {
+	return &ProtoConfig{}
+}
+
+func (pc *ProtoConfig) clone() *ProtoConfig {
+	pcCopy := *pc
+	return &pcCopy
+}
+
+// GetProtoConfig returns the proto extension's configuration for c, creating
+// a default one if none has been set yet.
+func GetProtoConfig(c *config.Config) *ProtoConfig {
+	pc, ok := c.Exts[protoName].(*ProtoConfig)
+	if !ok {
+		pc = newProtoConfig()
+	}
+	return pc
+}
@@ -37,8 +37,10 @@ func (_ *protoLang) GenerateRules(args language.GenerateArgs) (empty, gen []*rul
 
 	g := newGenerator(args)
 	pkgs := g.buildPackages(pc, args.Dir)
+	generatedNames := make(map[string]bool, len(pkgs))
 	for _, pkg := range pkgs {
 		r := g.generateProto(pc, pkg)
+		generatedNames[r.Name()] = true
 		if r.IsEmpty(protoKinds[r.Kind()]) {
 			empty = append(empty, r)
 		} else {
@@ -48,7 +50,7 @@ func (_ *protoLang) GenerateRules(args language.GenerateArgs) (empty, gen []*rul
 	sort.SliceStable(gen, func(i, j int) bool {
 		return gen[i].Name() < gen[j].Name()
 	})
-	empty = append(empty, g.generateEmpty()...)
+	empty = append(empty, g.generateEmpty(pc, generatedNames)...)
 	return empty, gen
 }
 
@@ -151,6 +153,47 @@ func (g *generator) buildPackages(pc *ProtoConfig, dir string) []*Package {
 		}
 		return pkgs
 
+	case FileMode:
+		// One package (and later, one proto_library) per .proto file, named
+		// after the file's stem, regardless of proto package grouping.
+		pkgs := make([]*Package, 0, len(g.regularProtoFiles))
+		for _, name := range g.regularProtoFiles {
+			info := protoFileInfo(dir, name)
+			pkg := newPackage(info.PackageName)
+			pkg.addFile(info)
+			pkgs = append(pkgs, pkg)
+		}
+		return pkgs
+
+	case ServiceMode:
+		// Two packages at most: one covering every file in the directory
+		// that declares a service, and one covering the rest (pure message
+		// files). This keeps service-consuming (typically gRPC) deps out of
+		// the build graph for code that only needs the messages.
+		var withServices, messagesOnly *Package
+		for _, name := range g.regularProtoFiles {
+			info := protoFileInfo(dir, name)
+			if info.HasServices {
+				if withServices == nil {
+					withServices = newPackage(info.PackageName)
+				}
+				withServices.addFile(info)
+			} else {
+				if messagesOnly == nil {
+					messagesOnly = newPackage(info.PackageName)
+				}
+				messagesOnly.addFile(info)
+			}
+		}
+		var pkgs []*Package
+		if messagesOnly != nil {
+			pkgs = append(pkgs, messagesOnly)
+		}
+		if withServices != nil {
+			pkgs = append(pkgs, withServices)
+		}
+		return pkgs
+
 	default:
 		return nil
 	}
@@ -202,13 +245,30 @@ func goPackageName(pkg *Package) string {
 	return ""
 }
 
+// fileStem returns the base name (minus ".proto") of the single file in a
+// FileMode package.
+func fileStem(pkg *Package) string {
+	for f := range pkg.Files {
+		return strings.TrimSuffix(f, ".proto")
+	}
+	return ""
+}
+
 // generateProto creates a new proto_library rule for a package. The rule may
 // be empty if there are no sources.
 func (g *generator) generateProto(pc *ProtoConfig, pkg *Package) *rule.Rule {
 	var name string
-	if pc.Mode == DefaultMode {
+	switch {
+	case pc.Mode == DefaultMode:
 		name = RuleName(goPackageName(pkg), pc.GoPrefix, g.rel)
-	} else {
+	case pc.Mode == FileMode:
+		// pkg holds exactly one file in this mode; name the rule after it
+		// so that touching one .proto file can't invalidate its siblings.
+		name = RuleName(fileStem(pkg), g.rel)
+	case pc.Mode == ServiceMode && pkg.HasServices:
+		base := strings.TrimSuffix(RuleName(pkg.Options[pc.groupOption], pkg.Name, g.rel), "_proto")
+		name = base + "_service_proto"
+	default:
 		name = RuleName(pkg.Options[pc.groupOption], pkg.Name, g.rel)
 	}
 	r := g.newRule("proto_library", name)
@@ -238,9 +298,21 @@ func (g *generator) generateProto(pc *ProtoConfig, pkg *Package) *rule.Rule {
 }
 
 // generateEmpty generates a list of proto_library rules that may be deleted.
-// This is generated from existing proto_library rules with srcs lists that
-// don't match any static or generated files.
-func (g *generator) generateEmpty() []*rule.Rule {
+// This includes existing proto_library rules with srcs lists that don't
+// match any static or generated files, as well as rules that Gazelle would
+// have generated under a previous Mode but wouldn't generate again this run
+// (e.g. after switching from DefaultMode to FileMode or ServiceMode).
+// generatedNames holds every rule name this run actually produced; in
+// FileMode and ServiceMode any proto_library whose name is not in it is
+// reaped, including a hand-written one whose srcs are still live.
+func (g *generator) generateEmpty(pc *ProtoConfig, generatedNames map[string]bool) []*rule.Rule {
+	// The name-based check below applies only in FileMode and ServiceMode,
+	// whose rule names change across runs as a file is added, removed, or
+	// gains a service.
+	// DefaultMode/PackageMode keep the original known-srcs-only check so an
+	// ambiguous directory (selectPackage returning an error) doesn't cause
+	// Gazelle to reap a perfectly live rule it merely declined to pick.
+	checkNames := pc.Mode == FileMode || pc.Mode == ServiceMode
 	if g.file == nil {
 		return nil
 	}
@@ -252,7 +324,6 @@ func (g *generator) generateEmpty() []*rule.Rule {
 		knownFiles[f] = true
 	}
 	var empty []*rule.Rule
-outer:
 	for _, r := range g.file.Rules {
 		if r.Kind() != g.c.MapKind("proto_library") {
 			continue
@@ -262,11 +333,16 @@ outer:
 			// srcs is not a string list; leave it alone
 			continue
 		}
-		for _, src := range r.AttrStrings("srcs") {
+		hasKnownSrc := false
+		for _, src := range srcs {
 			if knownFiles[src] {
-				continue outer
+				hasKnownSrc = true
+				break
 			}
 		}
+		if hasKnownSrc && (!checkNames || generatedNames[r.Name()]) {
+			continue // still current
+		}
 		empty = append(empty, g.newRule("proto_library", r.Name()))
 	}
 	return empty
@@ -0,0 +1,138 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+func writeProtoFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files := map[string]string{
+		"a.proto": "syntax = \"proto3\";\npackage foo;\nmessage A {}\n",
+		"b.proto": "syntax = \"proto3\";\npackage foo;\nmessage B {}\n",
+		"c.proto": "syntax = \"proto3\";\npackage foo;\nservice Greeter {\n  rpc SayHello(A) returns (B);\n}\n",
+	}
+	var names []string
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func newTestGenerator(dir string, regularProtoFiles []string) *generator {
+	args := language.GenerateArgs{
+		Config:       &config.Config{},
+		Dir:          dir,
+		Rel:          "foo",
+		RegularFiles: regularProtoFiles,
+	}
+	return newGenerator(args)
+}
+
+func TestBuildPackagesFileMode(t *testing.T) {
+	dir := t.TempDir()
+	names := writeProtoFiles(t, dir)
+	g := newTestGenerator(dir, names)
+	pc := &ProtoConfig{Mode: FileMode}
+
+	pkgs := g.buildPackages(pc, dir)
+	if len(pkgs) != len(names) {
+		t.Fatalf("buildPackages() returned %d packages, want one per regular proto file (%d)", len(pkgs), len(names))
+	}
+
+	seen := make(map[string]bool)
+	for _, pkg := range pkgs {
+		r := g.generateProto(pc, pkg)
+		if seen[r.Name()] {
+			t.Errorf("generateProto() produced duplicate rule name %q", r.Name())
+		}
+		seen[r.Name()] = true
+	}
+}
+
+func TestBuildPackagesServiceMode(t *testing.T) {
+	dir := t.TempDir()
+	names := writeProtoFiles(t, dir)
+	g := newTestGenerator(dir, names)
+	pc := &ProtoConfig{Mode: ServiceMode}
+
+	pkgs := g.buildPackages(pc, dir)
+	if len(pkgs) != 2 {
+		t.Fatalf("buildPackages() returned %d packages, want 2 (messages-only + with-services)", len(pkgs))
+	}
+
+	var sawServices, sawMessagesOnly bool
+	for _, pkg := range pkgs {
+		if pkg.HasServices {
+			sawServices = true
+			if len(pkg.Files) != 1 {
+				t.Errorf("service package Files = %v, want just c.proto", pkg.Files)
+			}
+		} else {
+			sawMessagesOnly = true
+			if len(pkg.Files) != 2 {
+				t.Errorf("messages-only package Files = %v, want a.proto and b.proto", pkg.Files)
+			}
+		}
+	}
+	if !sawServices || !sawMessagesOnly {
+		t.Fatalf("buildPackages() = %+v, want one package with services and one without", pkgs)
+	}
+}
+
+func TestGenerateEmptyReapsOnModeSwitch(t *testing.T) {
+	// Simulate a directory previously generated in DefaultMode (a single
+	// "foo_proto" rule covering every file) that has since switched to
+	// FileMode: foo_proto's srcs still exist on disk, but FileMode would
+	// never regenerate a rule under that name, so it must be reaped.
+	dir := t.TempDir()
+	names := writeProtoFiles(t, dir)
+
+	f := rule.EmptyFile("foo/BUILD.bazel", "foo")
+	stale := rule.NewRule("proto_library", "foo_proto")
+	stale.SetAttr("srcs", names)
+	stale.Insert(f)
+
+	args := language.GenerateArgs{
+		Config:       &config.Config{},
+		Dir:          dir,
+		Rel:          "foo",
+		RegularFiles: names,
+		File:         f,
+	}
+	g := newGenerator(args)
+	pc := &ProtoConfig{Mode: FileMode}
+
+	generatedNames := make(map[string]bool)
+	for _, pkg := range g.buildPackages(pc, dir) {
+		generatedNames[g.generateProto(pc, pkg).Name()] = true
+	}
+
+	empty := g.generateEmpty(pc, generatedNames)
+	if len(empty) != 1 || empty[0].Name() != "foo_proto" {
+		t.Fatalf("generateEmpty() = %v, want a single empty rule named foo_proto", empty)
+	}
+}
@@ -0,0 +1,79 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proto provides a Gazelle extension that generates proto_library
+// rules for .proto files.
+package proto
+
+import (
+	"flag"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/packagesindex"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+type protoLang struct {
+	language.BaseLang
+}
+
+// NewLanguage returns a new instance of the Gazelle extension for .proto
+// files.
+func NewLanguage() language.Language {
+	return &protoLang{}
+}
+
+func (*protoLang) Name() string { return protoName }
+
+func (*protoLang) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.Config) {}
+
+func (*protoLang) CheckFlags(fs *flag.FlagSet, c *config.Config) error { return nil }
+
+func (*protoLang) KnownDirectives() []string {
+	return []string{
+		"proto",
+		"proto_group",
+		"proto_strip_import_prefix",
+		"go_proto_compilers",
+		"go_grpc_compilers",
+		"emit_package_index",
+	}
+}
+
+func (*protoLang) Configure(c *config.Config, rel string, f *rule.File) {
+	if rel == "" {
+		// See the identical call in language/go's Configure: the Collector
+		// must exist before c.Exts gets cloned per directory, or each
+		// directory's Resolve would end up with its own independent one.
+		packagesindex.FromConfig(c)
+	}
+	pc := GetProtoConfig(c).clone()
+	if f != nil {
+		for _, d := range f.Directives {
+			switch d.Key {
+			case "proto":
+				if mode, err := ProtoModeFromString(d.Value); err == nil {
+					pc.Mode = mode
+				}
+			case "proto_group":
+				pc.groupOption = d.Value
+			case "emit_package_index":
+				pc.EmitPackageIndex = d.Value == "true"
+			}
+		}
+	}
+	c.Exts[protoName] = pc
+}
@@ -0,0 +1,62 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+// PackageKey is the name under which a Package is stashed as a private attr
+// on the proto_library rule that was generated for it, so later phases
+// (resolve, other languages) can look up its metadata.
+const PackageKey = "_proto_package"
+
+// Package collects the .proto files that belong together (as determined by
+// the configured Mode) along with metadata aggregated across those files.
+type Package struct {
+	Name    string
+	Files   map[string]bool
+	Imports map[string]bool
+	Options map[string]string
+
+	// HasServices is true if any file in the package declares a "service".
+	HasServices bool
+}
+
+func newPackage(name string) *Package {
+	return &Package{
+		Name:    name,
+		Files:   make(map[string]bool),
+		Imports: make(map[string]bool),
+		Options: make(map[string]string),
+	}
+}
+
+// addFile merges a parsed .proto file's metadata into the package.
+func (pkg *Package) addFile(info fileInfo) {
+	pkg.Files[info.Name] = true
+	for _, imp := range info.Imports {
+		pkg.Imports[imp] = true
+	}
+	for _, opt := range info.Options {
+		pkg.Options[opt.Key] = opt.Value
+	}
+	if info.HasServices {
+		pkg.HasServices = true
+	}
+}
+
+// addGenFile adds a generated .proto file (one not present in
+// RegularFiles) to the package without re-parsing it.
+func (pkg *Package) addGenFile(dir, name string) {
+	pkg.Files[name] = true
+}
@@ -0,0 +1,78 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// option is a single "option key = value;" statement parsed from a .proto
+// file.
+type option struct {
+	Key, Value string
+}
+
+// fileInfo holds metadata extracted from a single .proto file.
+type fileInfo struct {
+	Name        string
+	PackageName string
+	Imports     []string
+	Options     []option
+	HasServices bool
+}
+
+var (
+	packageRe = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	importRe  = regexp.MustCompile(`(?m)^\s*import\s+(?:public\s+|weak\s+)?"([^"]+)"\s*;`)
+	// The value is either a quoted string (which may itself contain ";", as
+	// in the common two-part `option go_package = "import/path;pkgname";`
+	// form) or a bare token running up to the terminating ";".
+	optionRe  = regexp.MustCompile(`(?m)^\s*option\s+([\w.()]+)\s*=\s*(?:"([^"]*)"|([^;]*?))\s*;`)
+	serviceRe = regexp.MustCompile(`(?m)^\s*service\s+\w+\s*\{`)
+)
+
+// protoFileInfo reads and parses a single .proto file. It performs a
+// lightweight regex-based extraction sufficient for Gazelle's needs; it
+// doesn't attempt to fully parse the proto grammar (string escaping, nested
+// messages, etc. are not handled).
+func protoFileInfo(dir, name string) fileInfo {
+	info := fileInfo{Name: name}
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return info
+	}
+	s := string(content)
+
+	if m := packageRe.FindStringSubmatch(s); m != nil {
+		info.PackageName = m[1]
+	}
+	for _, m := range importRe.FindAllStringSubmatch(s, -1) {
+		info.Imports = append(info.Imports, m[1])
+	}
+	for _, m := range optionRe.FindAllStringSubmatch(s, -1) {
+		value := m[2]
+		if m[3] != "" {
+			value = m[3]
+		}
+		info.Options = append(info.Options, option{Key: strings.TrimSpace(m[1]), Value: strings.TrimSpace(value)})
+	}
+	info.HasServices = serviceRe.MatchString(s)
+
+	return info
+}
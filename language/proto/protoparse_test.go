@@ -0,0 +1,84 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProtoFileInfoGoPackageOption(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+syntax = "proto3";
+
+package foo.bar;
+
+option go_package = "example.com/repo/foo;foopb";
+
+import "foo/other.proto";
+
+message M {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.proto"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := protoFileInfo(dir, "foo.proto")
+
+	if info.PackageName != "foo.bar" {
+		t.Errorf("PackageName = %q, want %q", info.PackageName, "foo.bar")
+	}
+	if len(info.Imports) != 1 || info.Imports[0] != "foo/other.proto" {
+		t.Errorf("Imports = %v, want [foo/other.proto]", info.Imports)
+	}
+	if info.HasServices {
+		t.Errorf("HasServices = true, want false")
+	}
+
+	var goPackage string
+	for _, opt := range info.Options {
+		if opt.Key == "go_package" {
+			goPackage = opt.Value
+		}
+	}
+	want := "example.com/repo/foo;foopb"
+	if goPackage != want {
+		t.Fatalf("go_package option = %q, want %q (the semicolon must survive quoting)", goPackage, want)
+	}
+}
+
+func TestProtoFileInfoHasServices(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+syntax = "proto3";
+
+package foo;
+
+service Greeter {
+  rpc SayHello(Request) returns (Response);
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "svc.proto"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := protoFileInfo(dir, "svc.proto")
+	if !info.HasServices {
+		t.Errorf("HasServices = false, want true")
+	}
+}
@@ -0,0 +1,83 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"path"
+	"sort"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/packagesindex"
+	"github.com/bazelbuild/bazel-gazelle/repo"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+func (*protoLang) Embeds(r *rule.Rule, from label.Label) []label.Label { return nil }
+
+// Imports returns one ImportSpec per .proto file a proto_library covers, so
+// other proto_library rules that import them can resolve a dep.
+func (*protoLang) Imports(c *config.Config, r *rule.Rule, f *rule.File) []resolve.ImportSpec {
+	if r.Kind() != "proto_library" {
+		return nil
+	}
+	var specs []resolve.ImportSpec
+	for _, src := range r.AttrStrings("srcs") {
+		specs = append(specs, resolve.ImportSpec{Lang: protoName, Imp: path.Join(f.Pkg, src)})
+	}
+	return specs
+}
+
+// Resolve turns the proto imports recorded on r (via config.GazelleImportsKey)
+// into deps on other proto_library rules, then, if emit_package_index is
+// enabled, records this rule in the run's shared packagesindex.Collector
+// now that deps are final.
+func (*protoLang) Resolve(c *config.Config, ix *resolve.RuleIndex, rc *repo.RemoteCache, r *rule.Rule, importsRaw interface{}, from label.Label) {
+	imports, _ := importsRaw.([]string)
+	depSet := make(map[string]bool)
+	for _, imp := range imports {
+		for _, res := range ix.FindRulesByImport(resolve.ImportSpec{Lang: protoName, Imp: imp}, protoName) {
+			if res.Label.Equal(from) {
+				continue
+			}
+			depSet[res.Label.Rel(from.Repo, from.Pkg).String()] = true
+		}
+	}
+	var deps []string
+	if len(depSet) > 0 {
+		deps = make([]string, 0, len(depSet))
+		for d := range depSet {
+			deps = append(deps, d)
+		}
+		sort.Strings(deps)
+		r.SetAttr("deps", deps)
+	}
+
+	pc := GetProtoConfig(c)
+	if !pc.EmitPackageIndex {
+		return
+	}
+	importPath, _ := r.PrivateAttr("go_package").(string)
+	packagesindex.FromConfig(c).Add(packagesindex.Package{
+		ImportPath: importPath,
+		Label:      from.String(),
+		Srcs:       r.AttrStrings("srcs"),
+		Deps:       deps,
+		GoFiles:    r.AttrStrings("srcs"),
+	})
+	packagesindex.Flush(c, c.RepoRoot)
+}
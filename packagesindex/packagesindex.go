@@ -0,0 +1,120 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package packagesindex builds the "gopackages-index.json" sidecar that lets
+// a gopackagesdriver binary answer go/packages queries from a static file
+// instead of running "bazel query" per request. Language extensions record
+// one Package entry per go_library/go_proto_library they generate (when
+// enabled by // gazelle:emit_package_index true) in the Collector shared via
+// FromConfig, from Resolve, once the rule's deps attr has its final,
+// resolved value, and write the accumulated entries out with Flush.
+package packagesindex
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+)
+
+// configKey is the key under which the run's Collector is stored in
+// config.Config.Exts. It's shared by every language extension so that Go and
+// proto rules land in the same index.
+const configKey = "packagesindex"
+
+// IndexFileName is the default name of the aggregated index file, written at
+// the repository root.
+const IndexFileName = "gopackages-index.json"
+
+// Package is one entry in the index: everything a gopackagesdriver needs to
+// answer a go/packages query for a single Bazel target without querying
+// Bazel.
+type Package struct {
+	ImportPath string   `json:"importPath"`
+	Label      string   `json:"label"`
+	Srcs       []string `json:"srcs"`
+	Deps       []string `json:"deps"`
+	GoFiles    []string `json:"goFiles"`
+
+	// HasServices is true for a go_proto_library whose underlying proto
+	// package declares a service (see language/proto's ServiceMode).
+	HasServices bool `json:"hasServices,omitempty"`
+}
+
+// Index is the top-level shape of gopackages-index.json.
+type Index struct {
+	Packages []Package `json:"packages"`
+}
+
+// Collector accumulates Package entries recorded during a single Gazelle
+// run. It's safe for concurrent use since rule generation for different
+// directories may run concurrently.
+type Collector struct {
+	mu   sync.Mutex
+	pkgs []Package
+}
+
+// FromConfig returns the Collector for this run, creating one on first use
+// and storing it in c.Exts so later calls (from any language extension, for
+// any directory) share the same accumulator.
+func FromConfig(c *config.Config) *Collector {
+	col, ok := c.Exts[configKey].(*Collector)
+	if !ok {
+		col = &Collector{}
+		c.Exts[configKey] = col
+	}
+	return col
+}
+
+// Add records a Package entry.
+func (col *Collector) Add(pkg Package) {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	col.pkgs = append(col.pkgs, pkg)
+}
+
+// Index returns a snapshot of the entries recorded so far, sorted by label
+// for stable output.
+func (col *Collector) Index() Index {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	pkgs := append([]Package{}, col.pkgs...)
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Label < pkgs[j].Label })
+	return Index{Packages: pkgs}
+}
+
+// Write marshals idx as indented JSON and writes it to path.
+func Write(path string, idx Index) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Flush writes the run's accumulated Collector out to
+// filepath.Join(repoRoot, IndexFileName). There's no dedicated end-of-run
+// driver hook in this tree to call this exactly once after every directory
+// has been resolved, so language extensions call it themselves from Resolve,
+// once per recorded entry; since the Collector is shared and cumulative,
+// the file left on disk after the run still reflects every entry recorded,
+// just via more intermediate writes than a single end-of-run flush would
+// need.
+func Flush(c *config.Config, repoRoot string) error {
+	return Write(filepath.Join(repoRoot, IndexFileName), FromConfig(c).Index())
+}
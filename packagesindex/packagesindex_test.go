@@ -0,0 +1,59 @@
+/* Copyright 2018 The Bazel Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packagesindex
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+)
+
+// cloneExts simulates the shallow copy the directory walk performs on
+// config.Config.Exts: a new map, but with the same entry values (pointers)
+// carried forward.
+func cloneExts(exts map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(exts))
+	for k, v := range exts {
+		clone[k] = v
+	}
+	return clone
+}
+
+func TestFromConfigSharedAcrossDirectoryClones(t *testing.T) {
+	root := &config.Config{Exts: map[string]interface{}{}}
+
+	// The Collector must be created at the root, before any per-directory
+	// clone of Exts, or each directory ends up with its own.
+	rootCol := FromConfig(root)
+
+	dirA := &config.Config{Exts: cloneExts(root.Exts)}
+	dirB := &config.Config{Exts: cloneExts(root.Exts)}
+
+	colA := FromConfig(dirA)
+	colB := FromConfig(dirB)
+
+	if colA != rootCol || colB != rootCol {
+		t.Fatal("FromConfig() returned a different Collector for a cloned directory config, want the same shared instance")
+	}
+
+	colA.Add(Package{ImportPath: "example.com/a"})
+	colB.Add(Package{ImportPath: "example.com/b"})
+
+	idx := rootCol.Index()
+	if len(idx.Packages) != 2 {
+		t.Fatalf("Index() = %+v, want entries recorded from both directories", idx.Packages)
+	}
+}